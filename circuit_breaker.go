@@ -0,0 +1,175 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/aerospike/aerospike-client-go/types"
+)
+
+// circuitState is the state of a single node's CircuitBreaker.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreakerPolicy configures when a per-node CircuitBreaker trips and
+// how long it stays open. It is evaluated per ResultCode class via Trips, so
+// a breaker can, for example, trip on SERVER_NOT_AVAILABLE after a handful of
+// failures while ignoring unrelated errors like KEY_NOT_FOUND_ERROR
+// entirely.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive tripping failures
+	// before the breaker opens.
+	FailureThreshold int
+	// ResetTimeout is how long the breaker stays open before allowing a
+	// single half-open probe through.
+	ResetTimeout time.Duration
+	// Trips reports whether err counts as a failure for this breaker. If
+	// nil, every non-nil error counts.
+	Trips func(err error) bool
+}
+
+// DefaultCircuitBreakerPolicy trips after 5 consecutive failures in
+// ErrClassClusterUnavailable or ErrClassTimeout, and probes again after 30
+// seconds.
+var DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+	FailureThreshold: 5,
+	ResetTimeout:     30 * time.Second,
+	Trips: func(err error) bool {
+		return errors.Is(err, ErrClassClusterUnavailable) || errors.Is(err, ErrClassTimeout)
+	},
+}
+
+// CircuitBreaker is a per-node circuit breaker: once FailureThreshold
+// consecutive tripping failures are recorded against a node, new commands to
+// that node fail immediately with ErrCircuitOpen instead of being sent, for
+// ResetTimeout. After that cool-down, a single half-open probe is allowed
+// through; its outcome either closes the breaker (success) or reopens it for
+// another ResetTimeout (failure).
+//
+// A CircuitBreaker is safe for concurrent use. Like RetryPolicy, it is not
+// yet wired into command execution -- nothing in this tree calls Allow or
+// RecordResult outside this file's own tests.
+type CircuitBreaker struct {
+	policy CircuitBreakerPolicy
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker creates a CircuitBreaker using policy.
+func NewCircuitBreaker(policy CircuitBreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{policy: policy, state: circuitClosed}
+}
+
+// Allow reports whether a command may be sent. It returns ErrCircuitOpen if
+// the breaker is open and the reset timeout has not yet elapsed. When the
+// reset timeout has elapsed, Allow lets exactly one caller through as the
+// half-open probe and returns ErrCircuitOpen to every other caller until
+// RecordResult reports the probe's outcome.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitClosed:
+		return nil
+	case circuitHalfOpen:
+		return ErrCircuitOpen
+	default: // circuitOpen
+		if time.Since(cb.openedAt) < cb.policy.ResetTimeout {
+			return ErrCircuitOpen
+		}
+		cb.state = circuitHalfOpen
+		return nil
+	}
+}
+
+// RecordResult reports the outcome of a command that Allow let through.
+func (cb *CircuitBreaker) RecordResult(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	trips := cb.policy.Trips
+	if trips == nil {
+		trips = func(err error) bool { return err != nil }
+	}
+
+	if cb.state == circuitHalfOpen {
+		if trips(err) {
+			cb.trip()
+		} else {
+			cb.reset()
+		}
+		return
+	}
+
+	if !trips(err) {
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.failures >= cb.policy.FailureThreshold {
+		cb.trip()
+	}
+}
+
+func (cb *CircuitBreaker) trip() {
+	cb.state = circuitOpen
+	cb.openedAt = time.Now()
+	cb.failures = 0
+}
+
+func (cb *CircuitBreaker) reset() {
+	cb.state = circuitClosed
+	cb.failures = 0
+}
+
+// circuitBreakers is a per-node registry of CircuitBreakers, keyed by node
+// name, meant to let command execution look up (or lazily create) the
+// breaker for whichever node it is about to send a command to. Nothing
+// constructs one outside this file's own tests yet.
+type circuitBreakers struct {
+	policy CircuitBreakerPolicy
+
+	mu      sync.Mutex
+	perNode map[string]*CircuitBreaker
+}
+
+func newCircuitBreakers(policy CircuitBreakerPolicy) *circuitBreakers {
+	return &circuitBreakers{policy: policy, perNode: map[string]*CircuitBreaker{}}
+}
+
+func (cbs *circuitBreakers) forNode(node string) *CircuitBreaker {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+
+	cb, ok := cbs.perNode[node]
+	if !ok {
+		cb = NewCircuitBreaker(cbs.policy)
+		cbs.perNode[node] = cb
+	}
+	return cb
+}
@@ -0,0 +1,101 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/aerospike/aerospike-client-go/types"
+)
+
+func TestCircuitBreakerTripsAndProbes(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerPolicy{
+		FailureThreshold: 2,
+		ResetTimeout:     10 * time.Millisecond,
+	})
+
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("expected closed breaker to allow, got %v", err)
+	}
+
+	cb.RecordResult(errors.New("boom"))
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("expected breaker to still be closed after 1 failure, got %v", err)
+	}
+
+	cb.RecordResult(errors.New("boom again"))
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open breaker after threshold failures, got %v", err)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("expected a half-open probe to be let through, got %v", err)
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a second caller to be rejected while a probe is in flight, got %v", err)
+	}
+
+	cb.RecordResult(nil)
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("expected breaker to close after a successful probe, got %v", err)
+	}
+}
+
+func TestCircuitBreakersForNodeIsPerNodeAndLazy(t *testing.T) {
+	cbs := newCircuitBreakers(CircuitBreakerPolicy{
+		FailureThreshold: 1,
+		ResetTimeout:     time.Minute,
+	})
+
+	a1 := cbs.forNode("node-a")
+	a2 := cbs.forNode("node-a")
+	if a1 != a2 {
+		t.Fatal("forNode should return the same breaker for the same node on repeated calls")
+	}
+
+	b := cbs.forNode("node-b")
+	if a1 == b {
+		t.Fatal("forNode should return distinct breakers for distinct nodes")
+	}
+
+	a1.RecordResult(errors.New("boom"))
+	if err := a1.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected node-a's breaker to be open, got %v", err)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected node-b's breaker to be unaffected by node-a's failure, got %v", err)
+	}
+}
+
+func TestExponentialBackoffRespectsCap(t *testing.T) {
+	b := ExponentialBackoff{Base: time.Millisecond, Cap: 50 * time.Millisecond, MaxRetries: 3}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		if d := b.Backoff(attempt, 0); d < 0 || d > b.Cap {
+			t.Fatalf("Backoff(%d) = %v, want in [0, %v]", attempt, d, b.Cap)
+		}
+	}
+
+	if !b.AllowRetry(nil, 3) {
+		t.Fatal("expected retry 3 to be allowed")
+	}
+	if b.AllowRetry(nil, 4) {
+		t.Fatal("expected retry 4 to be denied past MaxRetries")
+	}
+}
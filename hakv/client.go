@@ -0,0 +1,30 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hakv
+
+import (
+	as "github.com/aerospike/aerospike-client-go"
+)
+
+// asClient is the subset of *as.Client this package depends on. *as.Client
+// satisfies it implicitly; tests substitute a fake so Backend's write-policy
+// and error-handling logic can be exercised without a live Aerospike
+// connection.
+type asClient interface {
+	Get(policy *as.BasePolicy, key *as.Key, binNames ...string) (*as.Record, error)
+	Put(policy *as.WritePolicy, key *as.Key, bins as.BinMap) error
+	Delete(policy *as.WritePolicy, key *as.Key) (bool, error)
+	Query(policy *as.QueryPolicy, statement *as.Statement) (*as.Recordset, error)
+}
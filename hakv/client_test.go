@@ -0,0 +1,111 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hakv
+
+import (
+	"testing"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go"
+)
+
+// fakeClient is a minimal stand-in for *as.Client, implementing asClient so
+// Backend's write-policy logic can be exercised without a live Aerospike
+// connection.
+type fakeClient struct {
+	get    func(key *as.Key, binNames ...string) (*as.Record, error)
+	put    func(policy *as.WritePolicy, key *as.Key, bins as.BinMap) error
+	delete func(key *as.Key) (bool, error)
+}
+
+func (f *fakeClient) Get(_ *as.BasePolicy, key *as.Key, binNames ...string) (*as.Record, error) {
+	return f.get(key, binNames...)
+}
+
+func (f *fakeClient) Put(policy *as.WritePolicy, key *as.Key, bins as.BinMap) error {
+	return f.put(policy, key, bins)
+}
+
+func (f *fakeClient) Delete(_ *as.WritePolicy, key *as.Key) (bool, error) {
+	return f.delete(key)
+}
+
+func (f *fakeClient) Query(_ *as.QueryPolicy, _ *as.Statement) (*as.Recordset, error) {
+	panic("not used by these tests")
+}
+
+func newTestBackend() (*Backend, *fakeClient) {
+	fc := &fakeClient{}
+	return &Backend{client: fc, namespace: "ns", set: "set"}, fc
+}
+
+// Put, Lock and Renew all write the record List later discovers via a
+// secondary-index Query; List resolves each hit's key from
+// Record.Key.Value(), which the server only populates if the write set
+// WritePolicy.SendKey. Without it, List silently returns no keys at all.
+
+func TestPutSendsKey(t *testing.T) {
+	b, fc := newTestBackend()
+
+	var gotPolicy *as.WritePolicy
+	fc.put = func(policy *as.WritePolicy, key *as.Key, bins as.BinMap) error {
+		gotPolicy = policy
+		return nil
+	}
+
+	if err := b.Put("a/b", []byte("v")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPolicy == nil || !gotPolicy.SendKey {
+		t.Fatal("Put must set WritePolicy.SendKey so List can resolve the key from a later Query")
+	}
+}
+
+func TestLockSendsKey(t *testing.T) {
+	b, fc := newTestBackend()
+
+	var gotPolicy *as.WritePolicy
+	fc.put = func(policy *as.WritePolicy, key *as.Key, bins as.BinMap) error {
+		gotPolicy = policy
+		return nil
+	}
+
+	if _, err := b.Lock("a/b", "holder-1", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPolicy == nil || !gotPolicy.SendKey {
+		t.Fatal("Lock must set WritePolicy.SendKey so List can resolve the key from a later Query")
+	}
+}
+
+func TestRenewSendsKey(t *testing.T) {
+	b, fc := newTestBackend()
+
+	fc.get = func(key *as.Key, binNames ...string) (*as.Record, error) {
+		return &as.Record{Generation: 3, Bins: as.BinMap{holderBin: "holder-1"}}, nil
+	}
+	var gotPolicy *as.WritePolicy
+	fc.put = func(policy *as.WritePolicy, key *as.Key, bins as.BinMap) error {
+		gotPolicy = policy
+		return nil
+	}
+
+	if _, err := b.Renew("a/b", "holder-1", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPolicy == nil || !gotPolicy.SendKey {
+		t.Fatal("Renew must set WritePolicy.SendKey so List can resolve the key from a later Query")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hakv layers a Vault-style HA-locking key/value interface on top of
+// the Aerospike Client, so the client can be used directly as a distributed
+// lock / KV backend by services that need leader election (e.g. a Vault
+// physical backend). Leases are implemented with generation-check writes
+// (WritePolicy.GenerationPolicy = EXPECT_GEN_EQUAL) plus record TTL, so a
+// lease that is never renewed or released is reclaimed automatically by the
+// server without any background janitor process.
+package hakv
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go"
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+// isResultCode reports whether err is an *astypes.AerospikeError carrying
+// code, unwrapping through any wrapping via errors.As.
+func isResultCode(err error, code astypes.ResultCode) bool {
+	var aerr *astypes.AerospikeError
+	return errors.As(err, &aerr) && aerr.ResultCode() == code
+}
+
+// holderBin stores the identity of whoever currently holds the lock/value.
+// prefixBin stores the "directory" portion of the key (everything up to and
+// including the last "/") and is secondary-indexed so List can browse by
+// prefix without a full scan.
+const (
+	holderBin = "holder"
+	valueBin  = "value"
+	prefixBin = "prefix"
+)
+
+// ErrNotHeld is returned by Unlock when the lock is currently held by a
+// different holder. Renew reports that case as false, nil instead.
+var ErrNotHeld = errors.New("hakv: lock is not held by the given holder")
+
+// HABackend is a distributed lock/KV interface modeled on Vault's HABackend:
+// a lease on key can be acquired, renewed and released atomically, and the
+// current holder can be inspected without acquiring it.
+type HABackend interface {
+	// Lock attempts to acquire key for holder for ttl. It returns false, nil
+	// (not an error) if another holder currently owns the lock.
+	Lock(key, holder string, ttl time.Duration) (acquired bool, err error)
+	// Renew extends holder's lease on key by ttl. It returns false, nil if
+	// holder does not currently own the lock (e.g. the lease already expired
+	// and was claimed by someone else).
+	Renew(key, holder string, ttl time.Duration) (renewed bool, err error)
+	// Unlock releases key if it is currently held by holder. Unlocking a key
+	// held by a different holder returns ErrNotHeld; unlocking a key that is
+	// not locked at all returns nil.
+	Unlock(key, holder string) error
+	// Value returns the current holder of key, or "" if key is not locked.
+	Value(key string) (holder string, err error)
+}
+
+// Backend implements HABackend and a simple Get/Put/Delete/List KV API on top
+// of an *as.Client.
+type Backend struct {
+	client    asClient
+	namespace string
+	set       string
+}
+
+// NewBackend creates a Backend that stores its records in namespace.set using
+// client.
+func NewBackend(client *as.Client, namespace, set string) *Backend {
+	return &Backend{client: client, namespace: namespace, set: set}
+}
+
+func (b *Backend) key(k string) (*as.Key, error) {
+	key, err := as.NewKey(b.namespace, b.set, k)
+	if err != nil {
+		return nil, fmt.Errorf("hakv: invalid key %q: %w", k, err)
+	}
+	return key, nil
+}
+
+func prefixOf(key string) string {
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] == '/' {
+			return key[:i+1]
+		}
+	}
+	return ""
+}
+
+// Get returns the value stored at key, or (nil, nil) if key does not exist.
+func (b *Backend) Get(key string) ([]byte, error) {
+	k, err := b.key(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := b.client.Get(nil, k, valueBin)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	v, _ := rec.Bins[valueBin].([]byte)
+	return v, nil
+}
+
+// Put stores value at key, creating or overwriting the record as needed.
+func (b *Backend) Put(key string, value []byte) error {
+	k, err := b.key(key)
+	if err != nil {
+		return err
+	}
+
+	policy := as.NewWritePolicy(0, 0)
+	policy.SendKey = true // List resolves keys from Query results via Record.Key.Value()
+
+	bins := as.BinMap{
+		valueBin:  value,
+		prefixBin: prefixOf(key),
+	}
+	return b.client.Put(policy, k, bins)
+}
+
+// Delete removes key. Deleting a key that does not exist is not an error.
+func (b *Backend) Delete(key string) error {
+	k, err := b.key(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.Delete(nil, k)
+	return err
+}
+
+// List returns the full keys of the records directly under prefix, i.e.
+// those whose parent directory (everything up to and including the last
+// "/") is exactly prefix. Keys deeper in the tree are not returned, and no
+// entries are produced for sub-directories.
+// It is implemented as a secondary-index equality query over prefixBin, a
+// synthetic bin holding each key's parent directory, so it never needs a
+// full namespace scan.
+func (b *Backend) List(prefix string) ([]string, error) {
+	stmt := as.NewStatement(b.namespace, b.set)
+	if err := stmt.SetFilter(as.NewEqualFilter(prefixBin, prefix)); err != nil {
+		return nil, fmt.Errorf("hakv: building prefix filter: %w", err)
+	}
+
+	rs, err := b.client.Query(nil, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	var keys []string
+	for res := range rs.Results() {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if res.Record.Key.Value() != nil {
+			keys = append(keys, res.Record.Key.Value().String())
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hakv
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+func TestIsResultCode(t *testing.T) {
+	notFound := astypes.NewAerospikeError(astypes.KEY_NOT_FOUND_ERROR)
+
+	if !isResultCode(notFound, astypes.KEY_NOT_FOUND_ERROR) {
+		t.Fatal("expected isResultCode to match the exact AerospikeError")
+	}
+	if isResultCode(notFound, astypes.KEY_EXISTS_ERROR) {
+		t.Fatal("expected isResultCode not to match a different ResultCode")
+	}
+
+	wrapped := fmt.Errorf("get failed: %w", notFound)
+	if !isResultCode(wrapped, astypes.KEY_NOT_FOUND_ERROR) {
+		t.Fatal("expected isResultCode to see through fmt.Errorf wrapping")
+	}
+
+	if isResultCode(errors.New("not an aerospike error"), astypes.KEY_NOT_FOUND_ERROR) {
+		t.Fatal("expected isResultCode to reject unrelated errors")
+	}
+}
+
+func TestPrefixOf(t *testing.T) {
+	cases := map[string]string{
+		"a/b/c": "a/b/",
+		"a/":    "a/",
+		"a":     "",
+		"":      "",
+	}
+
+	for in, want := range cases {
+		if got := prefixOf(in); got != want {
+			t.Errorf("prefixOf(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
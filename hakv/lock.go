@@ -0,0 +1,142 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hakv
+
+import (
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go"
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+// Lock implements HABackend. It acquires the lock with a CREATE_ONLY write,
+// relying on record TTL to reclaim the lease if it is never released: once
+// the previous holder's record expires server-side, a new CREATE_ONLY write
+// succeeds and the lease changes hands without any explicit cleanup.
+func (b *Backend) Lock(key, holder string, ttl time.Duration) (bool, error) {
+	k, err := b.key(key)
+	if err != nil {
+		return false, err
+	}
+
+	policy := as.NewWritePolicy(0, uint32(ttl.Seconds()))
+	policy.RecordExistsAction = as.CREATE_ONLY
+	policy.SendKey = true // List resolves keys from Query results via Record.Key.Value()
+
+	bins := as.BinMap{
+		holderBin: holder,
+		prefixBin: prefixOf(key),
+	}
+
+	err = b.client.Put(policy, k, bins)
+	if err == nil {
+		return true, nil
+	}
+
+	if isResultCode(err, astypes.KEY_EXISTS_ERROR) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Renew implements HABackend. It extends holder's lease by ttl using a
+// generation-checked write, so a renewal racing against another node's Lock
+// (after this lease expired and was reclaimed) fails instead of silently
+// overwriting the new holder.
+func (b *Backend) Renew(key, holder string, ttl time.Duration) (bool, error) {
+	k, err := b.key(key)
+	if err != nil {
+		return false, err
+	}
+
+	rec, err := b.client.Get(nil, k, holderBin)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return false, nil
+		}
+		return false, err
+	}
+	if current, _ := rec.Bins[holderBin].(string); current != holder {
+		return false, nil
+	}
+
+	policy := as.NewWritePolicy(rec.Generation, uint32(ttl.Seconds()))
+	policy.GenerationPolicy = as.EXPECT_GEN_EQUAL
+	policy.RecordExistsAction = as.UPDATE_ONLY
+	policy.SendKey = true // List resolves keys from Query results via Record.Key.Value()
+
+	bins := as.BinMap{
+		holderBin: holder,
+		prefixBin: prefixOf(key),
+	}
+
+	err = b.client.Put(policy, k, bins)
+	if err == nil {
+		return true, nil
+	}
+	if isResultCode(err, astypes.GENERATION_ERROR) || isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Unlock implements HABackend. It only deletes the record if holder is still
+// the current owner, using the same generation check as Renew so a stale
+// Unlock call from a holder that already lost the lease cannot delete
+// someone else's lease.
+func (b *Backend) Unlock(key, holder string) error {
+	k, err := b.key(key)
+	if err != nil {
+		return err
+	}
+
+	rec, err := b.client.Get(nil, k, holderBin)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return nil
+		}
+		return err
+	}
+	if current, _ := rec.Bins[holderBin].(string); current != holder {
+		return ErrNotHeld
+	}
+
+	policy := as.NewWritePolicy(rec.Generation, 0)
+	policy.GenerationPolicy = as.EXPECT_GEN_EQUAL
+
+	_, err = b.client.Delete(policy, k)
+	return err
+}
+
+// Value implements HABackend. It returns "" if key is not currently locked.
+func (b *Backend) Value(key string) (string, error) {
+	k, err := b.key(key)
+	if err != nil {
+		return "", err
+	}
+
+	rec, err := b.client.Get(nil, k, holderBin)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	holder, _ := rec.Bins[holderBin].(string)
+	return holder, nil
+}
+
+var _ HABackend = (*Backend)(nil)
@@ -0,0 +1,161 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ExpvarSink is a Sink that publishes counters and latency sums/counts under
+// the standard library's expvar, so they show up alongside other process
+// metrics on /debug/vars without pulling in any third-party dependency.
+type ExpvarSink struct {
+	namespace string
+
+	mu       sync.Mutex
+	counters map[string]*expvar.Int
+	floats   map[string]*expvar.Float
+}
+
+// NewExpvarSink creates an ExpvarSink that publishes all of its variables
+// under "<namespace>.*". namespace defaults to "aerospike" if empty.
+func NewExpvarSink(namespace string) *ExpvarSink {
+	if namespace == "" {
+		namespace = "aerospike"
+	}
+	return &ExpvarSink{
+		namespace: namespace,
+		counters:  map[string]*expvar.Int{},
+		floats:    map[string]*expvar.Float{},
+	}
+}
+
+// counter returns the published *expvar.Int for name, publishing it the
+// first time it is needed. expvar.NewInt panics if name is already
+// published, which happens the moment two ExpvarSinks share a namespace
+// (e.g. the default "aerospike"), so an existing var under fullName is
+// reused instead of re-published.
+func (s *ExpvarSink) counter(name string) *expvar.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.counters[name]; ok {
+		return c
+	}
+
+	fullName := fmt.Sprintf("%s.%s", s.namespace, name)
+	c, ok := expvar.Get(fullName).(*expvar.Int)
+	if !ok {
+		c = expvar.NewInt(fullName)
+	}
+	s.counters[name] = c
+	return c
+}
+
+// float is the *expvar.Float counterpart of counter.
+func (s *ExpvarSink) float(name string) *expvar.Float {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f, ok := s.floats[name]; ok {
+		return f
+	}
+
+	fullName := fmt.Sprintf("%s.%s", s.namespace, name)
+	f, ok := expvar.Get(fullName).(*expvar.Float)
+	if !ok {
+		f = expvar.NewFloat(fullName)
+	}
+	s.floats[name] = f
+	return f
+}
+
+// ConnectionOpened implements Sink.
+func (s *ExpvarSink) ConnectionOpened(node string) {
+	s.counter(fmt.Sprintf("connections_opened.%s", node)).Add(1)
+}
+
+// ConnectionClosed implements Sink.
+func (s *ExpvarSink) ConnectionClosed(node string) {
+	s.counter(fmt.Sprintf("connections_closed.%s", node)).Add(1)
+}
+
+// ConnectionInUse implements Sink.
+func (s *ExpvarSink) ConnectionInUse(node string, count int) {
+	s.counter(fmt.Sprintf("connections_in_use.%s", node)).Set(int64(count))
+}
+
+// ConnectionPoolSize implements Sink.
+func (s *ExpvarSink) ConnectionPoolSize(node string, count int) {
+	s.counter(fmt.Sprintf("connections_pooled.%s", node)).Set(int64(count))
+}
+
+// expvarTimer accumulates elapsed time into an ExpvarSink's latency counters
+// on Stop.
+type expvarTimer struct {
+	sink  *ExpvarSink
+	name  string
+	start time.Time
+}
+
+// Stop implements Timer.
+func (t expvarTimer) Stop() {
+	elapsed := time.Since(t.start).Seconds()
+	t.sink.counter(fmt.Sprintf("%s.count", t.name)).Add(1)
+	t.sink.float(fmt.Sprintf("%s.seconds", t.name)).Add(elapsed)
+}
+
+// CommandStarted implements Sink.
+func (s *ExpvarSink) CommandStarted(node string, op Op, phase Phase) Timer {
+	return expvarTimer{
+		sink:  s,
+		name:  fmt.Sprintf("latency.%s.%s.%s", node, op, phase),
+		start: time.Now(),
+	}
+}
+
+// Retry implements Sink.
+func (s *ExpvarSink) Retry(node string, op Op) {
+	s.counter(fmt.Sprintf("retries.%s.%s", node, op)).Add(1)
+}
+
+// Timeout implements Sink.
+func (s *ExpvarSink) Timeout(node string, op Op) {
+	s.counter(fmt.Sprintf("timeouts.%s.%s", node, op)).Add(1)
+}
+
+// InDoubt implements Sink.
+func (s *ExpvarSink) InDoubt(node string, op Op) {
+	s.counter(fmt.Sprintf("in_doubt.%s.%s", node, op)).Add(1)
+}
+
+// FilteredOut implements Sink.
+func (s *ExpvarSink) FilteredOut(node string, op Op) {
+	s.counter(fmt.Sprintf("filtered_out.%s.%s", node, op)).Add(1)
+}
+
+// PartitionMapRefresh implements Sink.
+func (s *ExpvarSink) PartitionMapRefresh(success bool) {
+	if success {
+		s.counter("partition_map_refreshes.ok").Add(1)
+		return
+	}
+	s.counter("partition_map_refreshes.failed").Add(1)
+}
+
+var _ Sink = (*ExpvarSink)(nil)
@@ -0,0 +1,61 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import "testing"
+
+func TestExpvarSinkCounters(t *testing.T) {
+	s := NewExpvarSink("test_aerospike")
+
+	s.ConnectionOpened("node1")
+	s.ConnectionOpened("node1")
+	if got := s.counter("connections_opened.node1").Value(); got != 2 {
+		t.Fatalf("expected 2 connections opened, got %d", got)
+	}
+
+	s.Retry("node1", OpRead)
+	if got := s.counter("retries.node1.read").Value(); got != 1 {
+		t.Fatalf("expected 1 retry, got %d", got)
+	}
+
+	timer := s.CommandStarted("node1", OpRead, PhaseTotal)
+	timer.Stop()
+	if got := s.counter("latency.node1.read.total.count").Value(); got != 1 {
+		t.Fatalf("expected 1 latency sample, got %d", got)
+	}
+}
+
+func TestExpvarSinkSharingNamespaceDoesNotPanic(t *testing.T) {
+	// Two Sinks constructed with the same namespace (e.g. two Clients both
+	// left at the default "aerospike") publish the same expvar names;
+	// expvar.NewInt/NewFloat panic on a re-publish, so the second Sink must
+	// reuse the first one's published var instead of calling New* again.
+	a := NewExpvarSink("test_shared_namespace")
+	b := NewExpvarSink("test_shared_namespace")
+
+	a.ConnectionOpened("node1")
+	b.ConnectionOpened("node1")
+
+	if got := a.counter("connections_opened.node1").Value(); got != 2 {
+		t.Fatalf("expected the shared counter to observe both Sinks' increments, got %d", got)
+	}
+}
+
+func TestNopSinkIsNoOp(t *testing.T) {
+	var s NopSink
+	s.ConnectionOpened("node1")
+	s.Retry("node1", OpWrite)
+	s.CommandStarted("node1", OpWrite, PhaseSend).Stop()
+}
@@ -0,0 +1,139 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines a Sink interface for client-side observability --
+// connection pool activity, command latency and retry/timeout/filtered-out
+// counts -- plus expvar, Prometheus and OpenTelemetry implementations of it.
+// It is not yet wired into Cluster, Node, Connection or command execution;
+// nothing in the client calls a Sink today. A Sink is meant to eventually be
+// selected via a ClientPolicy field (the zero value a NopSink, so enabling
+// metrics would never change default behavior and leaving it unset would
+// cost nothing), but that integration is follow-up work, not part of this
+// package.
+package metrics
+
+// Op identifies the kind of command a latency measurement belongs to.
+type Op string
+
+// Op values for the command types the client instruments.
+const (
+	OpRead    Op = "read"
+	OpWrite   Op = "write"
+	OpDelete  Op = "delete"
+	OpBatch   Op = "batch"
+	OpScan    Op = "scan"
+	OpQuery   Op = "query"
+	OpUDF     Op = "udf"
+	OpOperate Op = "operate"
+	OpInfo    Op = "info"
+)
+
+// Phase identifies which part of a command's lifecycle a latency sample covers.
+type Phase string
+
+// Phase values a command timer can be stopped at.
+const (
+	// PhaseSend covers building and writing the request to the wire.
+	PhaseSend Phase = "send"
+	// PhaseWait covers time spent waiting for the server's response.
+	PhaseWait Phase = "wait"
+	// PhaseParse covers decoding the response into client structures.
+	PhaseParse Phase = "parse"
+	// PhaseTotal covers the command end-to-end, including retries.
+	PhaseTotal Phase = "total"
+)
+
+// Timer measures the duration of a single phase of a single command and
+// reports it to the Sink that created it when Stop is called. Obtaining a
+// Timer from a NopSink is always cheap and Stop is a no-op.
+type Timer interface {
+	Stop()
+}
+
+// Sink receives client metrics. Implementations must be safe for concurrent
+// use, since commands report metrics from many goroutines. All methods
+// except PartitionMapRefresh take a node name so per-node breakdowns are
+// possible; pass the
+// empty string for cluster-wide events that are not attributable to a node.
+type Sink interface {
+	// ConnectionOpened is called when a new connection to a node is established.
+	ConnectionOpened(node string)
+	// ConnectionClosed is called when a connection to a node is closed.
+	ConnectionClosed(node string)
+	// ConnectionInUse reports the current number of connections to node that
+	// are checked out of the pool and in use.
+	ConnectionInUse(node string, count int)
+	// ConnectionPoolSize reports the current number of idle pooled connections to node.
+	ConnectionPoolSize(node string, count int)
+
+	// CommandStarted begins timing the given phase of op against node.
+	// Callers must call Stop on the returned Timer exactly once.
+	CommandStarted(node string, op Op, phase Phase) Timer
+
+	// Retry is called each time a command is retried, after the retry is
+	// decided on but before it is executed.
+	Retry(node string, op Op)
+	// Timeout is called each time a command fails with a client-side timeout.
+	Timeout(node string, op Op)
+	// InDoubt is called each time a write completes (or fails) with its
+	// InDoubt flag set.
+	InDoubt(node string, op Op)
+	// FilteredOut is called each time a command returns types.ErrFilteredOut.
+	FilteredOut(node string, op Op)
+	// PartitionMapRefresh is called each time the cluster's partition map is
+	// refreshed, successfully or not.
+	PartitionMapRefresh(success bool)
+}
+
+// nopTimer is the Timer handed out by NopSink; Stop is a no-op.
+type nopTimer struct{}
+
+func (nopTimer) Stop() {}
+
+// NopSink is a Sink that discards everything it is given. It is meant to be
+// the default once a Sink is wired into ClientPolicy, so that metrics
+// collection has zero overhead unless a real Sink is configured.
+type NopSink struct{}
+
+// ConnectionOpened implements Sink.
+func (NopSink) ConnectionOpened(node string) {}
+
+// ConnectionClosed implements Sink.
+func (NopSink) ConnectionClosed(node string) {}
+
+// ConnectionInUse implements Sink.
+func (NopSink) ConnectionInUse(node string, count int) {}
+
+// ConnectionPoolSize implements Sink.
+func (NopSink) ConnectionPoolSize(node string, count int) {}
+
+// CommandStarted implements Sink.
+func (NopSink) CommandStarted(node string, op Op, phase Phase) Timer { return nopTimer{} }
+
+// Retry implements Sink.
+func (NopSink) Retry(node string, op Op) {}
+
+// Timeout implements Sink.
+func (NopSink) Timeout(node string, op Op) {}
+
+// InDoubt implements Sink.
+func (NopSink) InDoubt(node string, op Op) {}
+
+// FilteredOut implements Sink.
+func (NopSink) FilteredOut(node string, op Op) {}
+
+// PartitionMapRefresh implements Sink.
+func (NopSink) PartitionMapRefresh(success bool) {}
+
+var _ Sink = NopSink{}
@@ -0,0 +1,158 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// OtelSink is a Sink backed by an OpenTelemetry metric.MeterProvider. It
+// creates its instruments under the "github.com/aerospike/aerospike-client-go"
+// meter name so exported metric names are stable regardless of which
+// MeterProvider implementation is plugged in.
+type OtelSink struct {
+	connectionsOpened   metric.Int64Counter
+	connectionsClosed   metric.Int64Counter
+	connectionsInUse    metric.Int64UpDownCounter
+	connectionsPooled   metric.Int64UpDownCounter
+	commandLatency      metric.Float64Histogram
+	retries             metric.Int64Counter
+	timeouts            metric.Int64Counter
+	inDoubt             metric.Int64Counter
+	filteredOut         metric.Int64Counter
+	partitionMapRefresh metric.Int64Counter
+}
+
+// NewOtelSink creates an OtelSink using the instruments of the meter named
+// "github.com/aerospike/aerospike-client-go" obtained from provider.
+func NewOtelSink(provider metric.MeterProvider) (*OtelSink, error) {
+	meter := provider.Meter("github.com/aerospike/aerospike-client-go")
+
+	var err error
+	s := &OtelSink{}
+
+	if s.connectionsOpened, err = meter.Int64Counter("aerospike.client.connections.opened"); err != nil {
+		return nil, err
+	}
+	if s.connectionsClosed, err = meter.Int64Counter("aerospike.client.connections.closed"); err != nil {
+		return nil, err
+	}
+	if s.connectionsInUse, err = meter.Int64UpDownCounter("aerospike.client.connections.in_use"); err != nil {
+		return nil, err
+	}
+	if s.connectionsPooled, err = meter.Int64UpDownCounter("aerospike.client.connections.pooled"); err != nil {
+		return nil, err
+	}
+	if s.commandLatency, err = meter.Float64Histogram("aerospike.client.command.latency", metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if s.retries, err = meter.Int64Counter("aerospike.client.command.retries"); err != nil {
+		return nil, err
+	}
+	if s.timeouts, err = meter.Int64Counter("aerospike.client.command.timeouts"); err != nil {
+		return nil, err
+	}
+	if s.inDoubt, err = meter.Int64Counter("aerospike.client.command.in_doubt"); err != nil {
+		return nil, err
+	}
+	if s.filteredOut, err = meter.Int64Counter("aerospike.client.command.filtered_out"); err != nil {
+		return nil, err
+	}
+	if s.partitionMapRefresh, err = meter.Int64Counter("aerospike.client.cluster.partition_map_refreshes"); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ConnectionOpened implements Sink.
+func (s *OtelSink) ConnectionOpened(node string) {
+	s.connectionsOpened.Add(context.Background(), 1, metric.WithAttributes(attribute.String("node", node)))
+}
+
+// ConnectionClosed implements Sink.
+func (s *OtelSink) ConnectionClosed(node string) {
+	s.connectionsClosed.Add(context.Background(), 1, metric.WithAttributes(attribute.String("node", node)))
+}
+
+// ConnectionInUse implements Sink.
+func (s *OtelSink) ConnectionInUse(node string, count int) {
+	s.connectionsInUse.Add(context.Background(), int64(count), metric.WithAttributes(attribute.String("node", node)))
+}
+
+// ConnectionPoolSize implements Sink.
+func (s *OtelSink) ConnectionPoolSize(node string, count int) {
+	s.connectionsPooled.Add(context.Background(), int64(count), metric.WithAttributes(attribute.String("node", node)))
+}
+
+// otelTimer records elapsed time into a Float64Histogram on Stop.
+type otelTimer struct {
+	histogram metric.Float64Histogram
+	attrs     metric.MeasurementOption
+	start     time.Time
+}
+
+// Stop implements Timer.
+func (t otelTimer) Stop() {
+	t.histogram.Record(context.Background(), time.Since(t.start).Seconds(), t.attrs)
+}
+
+// CommandStarted implements Sink.
+func (s *OtelSink) CommandStarted(node string, op Op, phase Phase) Timer {
+	return otelTimer{
+		histogram: s.commandLatency,
+		attrs: metric.WithAttributes(
+			attribute.String("node", node),
+			attribute.String("op", string(op)),
+			attribute.String("phase", string(phase)),
+		),
+		start: time.Now(),
+	}
+}
+
+// Retry implements Sink.
+func (s *OtelSink) Retry(node string, op Op) {
+	s.retries.Add(context.Background(), 1, opAttrs(node, op))
+}
+
+// Timeout implements Sink.
+func (s *OtelSink) Timeout(node string, op Op) {
+	s.timeouts.Add(context.Background(), 1, opAttrs(node, op))
+}
+
+// InDoubt implements Sink.
+func (s *OtelSink) InDoubt(node string, op Op) {
+	s.inDoubt.Add(context.Background(), 1, opAttrs(node, op))
+}
+
+// FilteredOut implements Sink.
+func (s *OtelSink) FilteredOut(node string, op Op) {
+	s.filteredOut.Add(context.Background(), 1, opAttrs(node, op))
+}
+
+// PartitionMapRefresh implements Sink.
+func (s *OtelSink) PartitionMapRefresh(success bool) {
+	s.partitionMapRefresh.Add(context.Background(), 1, metric.WithAttributes(attribute.Bool("success", success)))
+}
+
+func opAttrs(node string, op Op) metric.AddOption {
+	return metric.WithAttributes(attribute.String("node", node), attribute.String("op", string(op)))
+}
+
+var _ Sink = (*OtelSink)(nil)
@@ -0,0 +1,172 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusSink is a Sink backed by Prometheus metrics. It implements
+// prometheus.Collector, so it can be registered directly with a
+// prometheus.Registry:
+//
+//   sink := metrics.NewPrometheusSink()
+//   prometheus.MustRegister(sink)
+type PrometheusSink struct {
+	connectionsOpened   *prometheus.CounterVec
+	connectionsClosed   *prometheus.CounterVec
+	connectionsInUse    *prometheus.GaugeVec
+	connectionsPooled   *prometheus.GaugeVec
+	commandLatency      *prometheus.HistogramVec
+	retries             *prometheus.CounterVec
+	timeouts            *prometheus.CounterVec
+	inDoubt             *prometheus.CounterVec
+	filteredOut         *prometheus.CounterVec
+	partitionMapRefresh *prometheus.CounterVec
+}
+
+// NewPrometheusSink creates a PrometheusSink. Its metric names are prefixed
+// with "aerospike_client_".
+func NewPrometheusSink() *PrometheusSink {
+	const ns = "aerospike_client"
+
+	return &PrometheusSink{
+		connectionsOpened: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "connections_opened_total", Help: "Total connections opened, by node.",
+		}, []string{"node"}),
+		connectionsClosed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "connections_closed_total", Help: "Total connections closed, by node.",
+		}, []string{"node"}),
+		connectionsInUse: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns, Name: "connections_in_use", Help: "Connections currently checked out of the pool, by node.",
+		}, []string{"node"}),
+		connectionsPooled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns, Name: "connections_pooled", Help: "Idle connections currently held in the pool, by node.",
+		}, []string{"node"}),
+		commandLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "command_latency_seconds", Help: "Command latency by node, op and phase.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"node", "op", "phase"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "retries_total", Help: "Total command retries, by node and op.",
+		}, []string{"node", "op"}),
+		timeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "timeouts_total", Help: "Total client-side timeouts, by node and op.",
+		}, []string{"node", "op"}),
+		inDoubt: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "in_doubt_total", Help: "Total writes completed with InDoubt set, by node and op.",
+		}, []string{"node", "op"}),
+		filteredOut: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "filtered_out_total", Help: "Total commands that returned ErrFilteredOut, by node and op.",
+		}, []string{"node", "op"}),
+		partitionMapRefresh: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "partition_map_refreshes_total", Help: "Total partition map refreshes, by outcome.",
+		}, []string{"outcome"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *PrometheusSink) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range s.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (s *PrometheusSink) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range s.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (s *PrometheusSink) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		s.connectionsOpened, s.connectionsClosed, s.connectionsInUse, s.connectionsPooled,
+		s.commandLatency, s.retries, s.timeouts, s.inDoubt, s.filteredOut, s.partitionMapRefresh,
+	}
+}
+
+// ConnectionOpened implements Sink.
+func (s *PrometheusSink) ConnectionOpened(node string) {
+	s.connectionsOpened.WithLabelValues(node).Inc()
+}
+
+// ConnectionClosed implements Sink.
+func (s *PrometheusSink) ConnectionClosed(node string) {
+	s.connectionsClosed.WithLabelValues(node).Inc()
+}
+
+// ConnectionInUse implements Sink.
+func (s *PrometheusSink) ConnectionInUse(node string, count int) {
+	s.connectionsInUse.WithLabelValues(node).Set(float64(count))
+}
+
+// ConnectionPoolSize implements Sink.
+func (s *PrometheusSink) ConnectionPoolSize(node string, count int) {
+	s.connectionsPooled.WithLabelValues(node).Set(float64(count))
+}
+
+// prometheusTimer observes elapsed time into a HistogramVec on Stop.
+type prometheusTimer struct {
+	observer prometheus.Observer
+	start    time.Time
+}
+
+// Stop implements Timer.
+func (t prometheusTimer) Stop() {
+	t.observer.Observe(time.Since(t.start).Seconds())
+}
+
+// CommandStarted implements Sink.
+func (s *PrometheusSink) CommandStarted(node string, op Op, phase Phase) Timer {
+	return prometheusTimer{
+		observer: s.commandLatency.WithLabelValues(node, string(op), string(phase)),
+		start:    time.Now(),
+	}
+}
+
+// Retry implements Sink.
+func (s *PrometheusSink) Retry(node string, op Op) {
+	s.retries.WithLabelValues(node, string(op)).Inc()
+}
+
+// Timeout implements Sink.
+func (s *PrometheusSink) Timeout(node string, op Op) {
+	s.timeouts.WithLabelValues(node, string(op)).Inc()
+}
+
+// InDoubt implements Sink.
+func (s *PrometheusSink) InDoubt(node string, op Op) {
+	s.inDoubt.WithLabelValues(node, string(op)).Inc()
+}
+
+// FilteredOut implements Sink.
+func (s *PrometheusSink) FilteredOut(node string, op Op) {
+	s.filteredOut.WithLabelValues(node, string(op)).Inc()
+}
+
+// PartitionMapRefresh implements Sink.
+func (s *PrometheusSink) PartitionMapRefresh(success bool) {
+	outcome := "ok"
+	if !success {
+		outcome = "failed"
+	}
+	s.partitionMapRefresh.WithLabelValues(outcome).Inc()
+}
+
+var _ Sink = (*PrometheusSink)(nil)
+var _ prometheus.Collector = (*PrometheusSink)(nil)
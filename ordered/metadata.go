@@ -0,0 +1,164 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import (
+	as "github.com/aerospike/aerospike-client-go"
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+const (
+	binCapacity    = "capacity"
+	binBoundaries  = "boundaries"
+	binShardIDs    = "shard_ids"
+	binNextShardID = "next_shard_id"
+	binEntries     = "entries"
+
+	// defaultCapacity is used when a collection is first created.
+	defaultCapacity = 100
+)
+
+// metadata is the decoded form of a collection's metadata record. Shards are
+// stored under stable IDs rather than positional indexes: boundaries[i] is
+// the lower bound of the (i+1)'th shard in value order, and shardIDs[i] is
+// the id of the i'th shard in that same order (so len(shardIDs) ==
+// len(boundaries)+1). Splitting a shard only ever allocates one new id and
+// inserts it into these two slices; no existing shard's id or record key
+// ever changes. generation is carried along so callers can do a
+// generation-checked write back, which is how SetCapacity and shard splits
+// avoid clobbering a concurrent update.
+type metadata struct {
+	capacity    int
+	boundaries  []float64
+	shardIDs    []int
+	nextShardID int
+	generation  uint32
+}
+
+func (c *Collection) loadMetadata() (*metadata, error) {
+	key, err := as.NewKey(c.namespace, c.set, metaKey(c.name))
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := c.client.Get(nil, key)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return c.createMetadata(key)
+		}
+		return nil, err
+	}
+
+	return metadataFromRecord(rec), nil
+}
+
+func (c *Collection) createMetadata(key *as.Key) (*metadata, error) {
+	policy := as.NewWritePolicy(0, 0)
+	policy.RecordExistsAction = as.CREATE_ONLY
+
+	bins := as.BinMap{
+		binCapacity:    defaultCapacity,
+		binBoundaries:  []interface{}{},
+		binShardIDs:    []interface{}{0},
+		binNextShardID: 1,
+	}
+
+	err := c.client.Put(policy, key, bins)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_EXISTS_ERROR) {
+			// Lost the create race; read back what the winner wrote.
+			rec, gerr := c.client.Get(nil, key)
+			if gerr != nil {
+				return nil, gerr
+			}
+			return metadataFromRecord(rec), nil
+		}
+		return nil, err
+	}
+
+	return &metadata{capacity: defaultCapacity, shardIDs: []int{0}, nextShardID: 1}, nil
+}
+
+func metadataFromRecord(rec *as.Record) *metadata {
+	m := &metadata{generation: rec.Generation}
+
+	if cap, ok := rec.Bins[binCapacity].(int); ok {
+		m.capacity = cap
+	} else {
+		m.capacity = defaultCapacity
+	}
+
+	if raw, ok := rec.Bins[binBoundaries].([]interface{}); ok {
+		m.boundaries = make([]float64, 0, len(raw))
+		for _, v := range raw {
+			if f, ok := toFloat64(v); ok {
+				m.boundaries = append(m.boundaries, f)
+			}
+		}
+	}
+
+	if raw, ok := rec.Bins[binShardIDs].([]interface{}); ok {
+		m.shardIDs = make([]int, 0, len(raw))
+		for _, v := range raw {
+			if id, ok := toFloat64(v); ok {
+				m.shardIDs = append(m.shardIDs, int(id))
+			}
+		}
+	}
+	if len(m.shardIDs) == 0 {
+		m.shardIDs = []int{0}
+	}
+
+	if next, ok := rec.Bins[binNextShardID].(int); ok {
+		m.nextShardID = next
+	} else {
+		m.nextShardID = 1
+	}
+
+	return m
+}
+
+// shardCount returns the number of shards tracked by the metadata.
+func (m *metadata) shardCount() int {
+	return len(m.shardIDs)
+}
+
+// saveMetadata writes m back to the metadata record under a generation check, so a
+// concurrent SetCapacity or shard split does not silently lose an update.
+func (c *Collection) saveMetadata(m *metadata) error {
+	key, err := as.NewKey(c.namespace, c.set, metaKey(c.name))
+	if err != nil {
+		return err
+	}
+
+	boundaries := make([]interface{}, len(m.boundaries))
+	for i, b := range m.boundaries {
+		boundaries[i] = b
+	}
+	shardIDs := make([]interface{}, len(m.shardIDs))
+	for i, id := range m.shardIDs {
+		shardIDs[i] = id
+	}
+
+	policy := as.NewWritePolicy(m.generation, 0)
+	policy.GenerationPolicy = as.EXPECT_GEN_EQUAL
+
+	return c.client.Put(policy, key, as.BinMap{
+		binCapacity:    m.capacity,
+		binBoundaries:  boundaries,
+		binShardIDs:    shardIDs,
+		binNextShardID: m.nextShardID,
+	})
+}
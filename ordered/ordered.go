@@ -0,0 +1,260 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ordered provides a client-side ordered-collection, reimplementing
+// the surface of the server's now-removed LargeList (LDT) feature -- Add,
+// Find, Remove, Range, Scan, Size, SetCapacity and GetCapacity -- on top of
+// ordinary CDT List operations. Entries are sharded across multiple records
+// by value range, each shard holding one ordered CDT list, with a small
+// metadata record tracking each shard's value-range boundary, its stable
+// shard id and the collection's capacity. A shard that grows past
+// rebalanceThreshold entries is split in two as part of the Add call that
+// pushed it over the threshold, under a generation check, so no single
+// record grows without bound. Splitting only ever allocates one new shard
+// id and touches the shard being split, the new shard and the metadata
+// record -- never any other shard -- so the cost of a split does not grow
+// with the number of shards the collection already has.
+package ordered
+
+import (
+	"errors"
+	"fmt"
+
+	as "github.com/aerospike/aerospike-client-go"
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+// isResultCode reports whether err is an *astypes.AerospikeError carrying
+// code, unwrapping through any wrapping via errors.As.
+func isResultCode(err error, code astypes.ResultCode) bool {
+	var aerr *astypes.AerospikeError
+	return errors.As(err, &aerr) && aerr.ResultCode() == code
+}
+
+// rebalanceThreshold is the shard size, in entries, above which Add splits
+// the shard it just wrote to.
+const rebalanceThreshold = 1000
+
+// Collection is a client-side ordered collection of values, addressed by a
+// single Aerospike key namespace/set/name the way a LargeList bin used to be
+// addressed by namespace/set/key/bin.
+type Collection struct {
+	client    asClient
+	namespace string
+	set       string
+	name      string
+}
+
+// NewCollection creates a Collection backed by client, storing its shard and
+// metadata records in namespace.set under keys derived from name. name must
+// be unique per collection within the set, the way a bin name used to
+// identify a LargeList within a record.
+func NewCollection(client *as.Client, namespace, set, name string) *Collection {
+	return &Collection{client: client, namespace: namespace, set: set, name: name}
+}
+
+// toFloat64 converts a value to the float64 sort key used to place it in a
+// shard. Only the ordered numeric and string-length-independent types a
+// LargeList historically held are supported; anything else returns false.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func errUnsupported(v interface{}) error {
+	return fmt.Errorf("ordered: unsupported value type %T", v)
+}
+
+// shardAsKey returns the record key for the shard with stable id shardID.
+// Unlike a shard's position in value order, shardID never changes once
+// assigned, so a split only ever allocates a new id rather than renaming
+// every shard after the split point.
+func (c *Collection) shardAsKey(shardID int) (*as.Key, error) {
+	return as.NewKey(c.namespace, c.set, shardKey(c.name, shardID))
+}
+
+// Add inserts value into the collection, keeping its shard's CDT list
+// sorted. If the target shard grows past rebalanceThreshold entries, it is
+// split into two shards before Add returns.
+func (c *Collection) Add(value interface{}) error {
+	key, ok := toFloat64(value)
+	if !ok {
+		return fmt.Errorf("ordered: unsupported value type %T", value)
+	}
+
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	pos := shardFor(meta.boundaries, key)
+	shardRecKey, err := c.shardAsKey(meta.shardIDs[pos])
+	if err != nil {
+		return err
+	}
+
+	rec, err := c.client.Operate(nil, shardRecKey,
+		as.ListAppendOp(binEntries, value),
+		as.ListSortOp(binEntries, as.ListSortFlagsDefault),
+		as.ListSizeOp(binEntries),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Multiple ops against the same bin come back as one result per op, in
+	// call order, not a single scalar -- ListSizeOp is the third op above.
+	results, _ := rec.Bins[binEntries].([]interface{})
+	if len(results) != 3 {
+		return fmt.Errorf("ordered: unexpected Operate result shape for %s: %v", binEntries, rec.Bins[binEntries])
+	}
+
+	size, _ := results[2].(int)
+	if size > rebalanceThreshold {
+		return c.rebalance(meta, pos)
+	}
+	return nil
+}
+
+// Find returns true if value is present in the collection.
+func (c *Collection) Find(value interface{}) (bool, error) {
+	key, ok := toFloat64(value)
+	if !ok {
+		return false, fmt.Errorf("ordered: unsupported value type %T", value)
+	}
+
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return false, err
+	}
+
+	pos := shardFor(meta.boundaries, key)
+	shardRecKey, err := c.shardAsKey(meta.shardIDs[pos])
+	if err != nil {
+		return false, err
+	}
+
+	rec, err := c.client.Operate(nil, shardRecKey,
+		as.ListGetByValueOp(binEntries, value, as.ListReturnTypeCount),
+	)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	count, _ := rec.Bins[binEntries].(int)
+	return count > 0, nil
+}
+
+// Remove deletes value from the collection, if present.
+func (c *Collection) Remove(value interface{}) error {
+	key, ok := toFloat64(value)
+	if !ok {
+		return fmt.Errorf("ordered: unsupported value type %T", value)
+	}
+
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	pos := shardFor(meta.boundaries, key)
+	shardRecKey, err := c.shardAsKey(meta.shardIDs[pos])
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Operate(nil, shardRecKey,
+		as.ListRemoveByValueOp(binEntries, value, as.ListReturnTypeNone),
+	)
+	if err != nil {
+		if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Size returns the total number of entries across all shards.
+func (c *Collection) Size() (int, error) {
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, id := range meta.shardIDs {
+		shardRecKey, err := c.shardAsKey(id)
+		if err != nil {
+			return 0, err
+		}
+
+		rec, err := c.client.Operate(nil, shardRecKey, as.ListSizeOp(binEntries))
+		if err != nil {
+			if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+				continue
+			}
+			return 0, err
+		}
+		n, _ := rec.Bins[binEntries].(int)
+		total += n
+	}
+	return total, nil
+}
+
+// GetCapacity returns the collection's configured capacity.
+func (c *Collection) GetCapacity() (int, error) {
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return 0, err
+	}
+	return meta.capacity, nil
+}
+
+// SetCapacity updates the collection's configured capacity. It does not by
+// itself evict entries; it is advisory, matching the capacity semantics of
+// the original LargeList API.
+func (c *Collection) SetCapacity(capacity int) error {
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return err
+	}
+	meta.capacity = capacity
+	return c.saveMetadata(meta)
+}
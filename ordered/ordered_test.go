@@ -0,0 +1,114 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import (
+	"errors"
+	"testing"
+
+	as "github.com/aerospike/aerospike-client-go"
+)
+
+// fakeClient is a minimal stand-in for *as.Client, implementing asClient so
+// Add's rebalance-trigger logic can be exercised without a live Aerospike
+// connection. Each test wires only the hooks it needs.
+type fakeClient struct {
+	get     func(key *as.Key, binNames ...string) (*as.Record, error)
+	put     func(key *as.Key, bins as.BinMap) error
+	operate func(key *as.Key, ops ...*as.Operation) (*as.Record, error)
+}
+
+func (f *fakeClient) Get(_ *as.BasePolicy, key *as.Key, binNames ...string) (*as.Record, error) {
+	return f.get(key, binNames...)
+}
+
+func (f *fakeClient) Put(_ *as.WritePolicy, key *as.Key, bins as.BinMap) error {
+	return f.put(key, bins)
+}
+
+func (f *fakeClient) Operate(_ *as.WritePolicy, key *as.Key, ops ...*as.Operation) (*as.Record, error) {
+	return f.operate(key, ops...)
+}
+
+func newTestCollection() (*Collection, *fakeClient) {
+	fc := &fakeClient{}
+	return &Collection{client: fc, namespace: "ns", set: "set", name: "coll"}, fc
+}
+
+// metadataRecord builds the canned Get response loadMetadata expects for an
+// already-created collection with a single shard.
+func metadataRecord() *as.Record {
+	return &as.Record{Generation: 5, Bins: as.BinMap{
+		binCapacity:    defaultCapacity,
+		binBoundaries:  []interface{}{},
+		binShardIDs:    []interface{}{0},
+		binNextShardID: 1,
+	}}
+}
+
+func TestAddTriggersRebalanceWhenShardIsOverThreshold(t *testing.T) {
+	c, fc := newTestCollection()
+
+	rebalanceAttempted := false
+	fc.get = func(key *as.Key, binNames ...string) (*as.Record, error) {
+		if key.Value().String() == metaKey(c.name) {
+			return metadataRecord(), nil
+		}
+		// rebalance's first step is Get(nil, shardRecKey, binEntries); seeing
+		// it proves Add actually invoked rebalance, not just that it ran the
+		// Operate call.
+		rebalanceAttempted = true
+		return nil, errors.New("stop before actually splitting")
+	}
+	fc.operate = func(key *as.Key, ops ...*as.Operation) (*as.Record, error) {
+		// One result per op: ListAppendOp, ListSortOp, ListSizeOp -- the size
+		// is the last element, not the bin value itself.
+		return &as.Record{Bins: as.BinMap{
+			binEntries: []interface{}{nil, nil, rebalanceThreshold + 1},
+		}}, nil
+	}
+
+	if err := c.Add(1); err == nil {
+		t.Fatal("expected Add to surface the error rebalance hit, proving rebalance was reached")
+	}
+	if !rebalanceAttempted {
+		t.Fatal("Add did not trigger rebalance for a shard over rebalanceThreshold")
+	}
+}
+
+func TestAddDoesNotRebalanceAtOrUnderThreshold(t *testing.T) {
+	c, fc := newTestCollection()
+
+	rebalanceAttempted := false
+	fc.get = func(key *as.Key, binNames ...string) (*as.Record, error) {
+		if key.Value().String() == metaKey(c.name) {
+			return metadataRecord(), nil
+		}
+		rebalanceAttempted = true
+		return nil, errors.New("rebalance should not have been attempted")
+	}
+	fc.operate = func(key *as.Key, ops ...*as.Operation) (*as.Record, error) {
+		return &as.Record{Bins: as.BinMap{
+			binEntries: []interface{}{nil, nil, rebalanceThreshold},
+		}}, nil
+	}
+
+	if err := c.Add(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rebalanceAttempted {
+		t.Fatal("Add rebalanced a shard that was at, not over, rebalanceThreshold")
+	}
+}
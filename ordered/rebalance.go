@@ -0,0 +1,146 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	as "github.com/aerospike/aerospike-client-go"
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+// rebalance splits the shard at position pos in two once it has grown past
+// rebalanceThreshold. It touches exactly one existing shard record (the one
+// being split), one brand new shard record, and the metadata record --
+// never the other shards -- because shards are keyed by a stable id that is
+// assigned once and never reused, rather than by their position in value
+// order. A split only ever allocates meta.nextShardID and inserts it (and
+// its boundary) into meta.boundaries/meta.shardIDs; every other shard's id
+// and record key are untouched.
+//
+// The new shard is created, and the metadata committed to point at it,
+// before the original shard is trimmed down to its lower half. That order
+// means a failure between those last two steps leaves the original shard
+// holding a few entries beyond its new boundary -- duplicated in both
+// shards, not lost -- until the next successful split re-trims it; it never
+// leaves a boundary pointing at a shard that doesn't exist yet.
+func (c *Collection) rebalance(meta *metadata, pos int) error {
+	shardID := meta.shardIDs[pos]
+	shardRecKey, err := c.shardAsKey(shardID)
+	if err != nil {
+		return err
+	}
+
+	rec, err := c.client.Get(nil, shardRecKey, binEntries)
+	if err != nil {
+		return err
+	}
+
+	raw, _ := rec.Bins[binEntries].([]interface{})
+	lower, upper, upperBound, err := splitEntries(raw)
+	if err == errTooFewToSplit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ordered: splitting shard %d: %w", shardID, err)
+	}
+
+	newShardID := meta.nextShardID
+	newShardRecKey, err := c.shardAsKey(newShardID)
+	if err != nil {
+		return err
+	}
+
+	createPolicy := as.NewWritePolicy(0, 0)
+	createPolicy.RecordExistsAction = as.CREATE_ONLY
+	if err := c.client.Put(createPolicy, newShardRecKey, as.BinMap{binEntries: upper}); err != nil {
+		if isResultCode(err, astypes.KEY_EXISTS_ERROR) {
+			// Another goroutine already split this shard; nothing left to do.
+			return nil
+		}
+		return err
+	}
+
+	meta.boundaries = insertBoundary(meta.boundaries, pos, upperBound)
+	meta.shardIDs = insertShardID(meta.shardIDs, pos, newShardID)
+	meta.nextShardID++
+
+	if err := c.saveMetadata(meta); err != nil {
+		// The metadata write lost a race (e.g. a concurrent SetCapacity or
+		// another split). Leave the new shard record in place: its entries
+		// are still present in the original shard too, so nothing is lost,
+		// and a future split will simply reuse a higher id.
+		return err
+	}
+
+	lowerPolicy := as.NewWritePolicy(rec.Generation, 0)
+	lowerPolicy.GenerationPolicy = as.EXPECT_GEN_EQUAL
+	return c.client.Put(lowerPolicy, shardRecKey, as.BinMap{binEntries: lower})
+}
+
+// errTooFewToSplit is returned by splitEntries when raw has fewer than two
+// entries, i.e. there is nothing sensible to split.
+var errTooFewToSplit = errors.New("ordered: too few entries to split")
+
+// splitEntries sorts raw and divides it into its lower and upper halves,
+// returning the smallest value of the upper half as the new shard boundary.
+// It is a pure function so the split logic can be unit tested without a
+// live Aerospike client.
+func splitEntries(raw []interface{}) (lower, upper []interface{}, upperBound float64, err error) {
+	if len(raw) < 2 {
+		return nil, nil, 0, errTooFewToSplit
+	}
+
+	sorted := make([]interface{}, len(raw))
+	copy(sorted, raw)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, _ := toFloat64(sorted[i])
+		b, _ := toFloat64(sorted[j])
+		return a < b
+	})
+
+	mid := len(sorted) / 2
+	lower, upper = sorted[:mid], sorted[mid:]
+
+	upperBound, ok := toFloat64(upper[0])
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("unsupported value type %T", upper[0])
+	}
+	return lower, upper, upperBound, nil
+}
+
+// insertBoundary returns boundaries with newBoundary inserted at position
+// pos, the way a shard split at position pos introduces one new boundary
+// without disturbing any other entry.
+func insertBoundary(boundaries []float64, pos int, newBoundary float64) []float64 {
+	out := make([]float64, 0, len(boundaries)+1)
+	out = append(out, boundaries[:pos]...)
+	out = append(out, newBoundary)
+	out = append(out, boundaries[pos:]...)
+	return out
+}
+
+// insertShardID returns shardIDs with newID inserted directly after
+// position pos, the way a shard split at position pos introduces one new
+// shard immediately after the one that was split.
+func insertShardID(shardIDs []int, pos int, newID int) []int {
+	out := make([]int, 0, len(shardIDs)+1)
+	out = append(out, shardIDs[:pos+1]...)
+	out = append(out, newID)
+	out = append(out, shardIDs[pos+1:]...)
+	return out
+}
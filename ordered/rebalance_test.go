@@ -0,0 +1,72 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitEntriesTooFew(t *testing.T) {
+	if _, _, _, err := splitEntries(nil); !errors.Is(err, errTooFewToSplit) {
+		t.Fatalf("splitEntries(nil) err = %v, want errTooFewToSplit", err)
+	}
+	if _, _, _, err := splitEntries([]interface{}{1}); !errors.Is(err, errTooFewToSplit) {
+		t.Fatalf("splitEntries([1]) err = %v, want errTooFewToSplit", err)
+	}
+}
+
+func TestSplitEntriesSortsAndDivides(t *testing.T) {
+	lower, upper, bound, err := splitEntries([]interface{}{4, 1, 3, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lower, []interface{}{1, 2}) {
+		t.Errorf("lower = %v, want [1 2]", lower)
+	}
+	if !reflect.DeepEqual(upper, []interface{}{3, 4}) {
+		t.Errorf("upper = %v, want [3 4]", upper)
+	}
+	if bound != 3 {
+		t.Errorf("bound = %v, want 3", bound)
+	}
+}
+
+func TestInsertBoundaryAndShardID(t *testing.T) {
+	boundaries := insertBoundary([]float64{10, 20}, 1, 15)
+	if !reflect.DeepEqual(boundaries, []float64{10, 15, 20}) {
+		t.Fatalf("boundaries = %v, want [10 15 20]", boundaries)
+	}
+
+	shardIDs := insertShardID([]int{0, 2, 5}, 1, 99)
+	if !reflect.DeepEqual(shardIDs, []int{0, 2, 99, 5}) {
+		t.Fatalf("shardIDs = %v, want [0 2 99 5]", shardIDs)
+	}
+
+	// A split never disturbs the id of any shard other than the new one:
+	// every original id must still appear, in its original relative order.
+	for _, want := range []int{0, 2, 5} {
+		found := false
+		for _, got := range shardIDs {
+			if got == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("shard id %d missing after insertShardID", want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import (
+	as "github.com/aerospike/aerospike-client-go"
+	astypes "github.com/aerospike/aerospike-client-go/types"
+)
+
+// Iterator walks a Collection's entries in order, one shard at a time.
+type Iterator struct {
+	c        *Collection
+	shardIDs []int
+	idx      int
+	buf      []interface{}
+	pos      int
+	err      error
+}
+
+// Scan returns an Iterator over every entry in the collection, in order.
+func (c *Collection) Scan() (*Iterator, error) {
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{c: c, shardIDs: meta.shardIDs}, nil
+}
+
+// Next reports whether a value is available, reading the next shard once
+// the current one has been consumed by Value. Once Next returns false,
+// check Err to distinguish end-of-collection from a read error.
+func (it *Iterator) Next() bool {
+	for it.pos >= len(it.buf) {
+		if it.idx >= len(it.shardIDs) {
+			return false
+		}
+
+		key, err := it.c.shardAsKey(it.shardIDs[it.idx])
+		it.idx++
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		rec, err := it.c.client.Get(nil, key, binEntries)
+		if err != nil {
+			if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+				continue
+			}
+			it.err = err
+			return false
+		}
+
+		it.buf, _ = rec.Bins[binEntries].([]interface{})
+		it.pos = 0
+	}
+	return true
+}
+
+// Value returns the current entry and moves past it, so it is called once
+// after each Next that returns true.
+func (it *Iterator) Value() interface{} {
+	v := it.buf[it.pos]
+	it.pos++
+	return v
+}
+
+// Err returns the first error encountered during iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Range returns every entry with a value in [from, to), in order: from is
+// inclusive and to is exclusive, so entries equal to to are not returned.
+// It only reads the shards whose range overlaps [from, to], rather than
+// scanning the whole collection.
+func (c *Collection) Range(from, to interface{}) ([]interface{}, error) {
+	fromKey, ok := toFloat64(from)
+	if !ok {
+		return nil, errUnsupported(from)
+	}
+	toKey, ok := toFloat64(to)
+	if !ok {
+		return nil, errUnsupported(to)
+	}
+
+	meta, err := c.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	first := shardFor(meta.boundaries, fromKey)
+	last := shardFor(meta.boundaries, toKey)
+
+	var out []interface{}
+	for pos := first; pos <= last; pos++ {
+		key, err := c.shardAsKey(meta.shardIDs[pos])
+		if err != nil {
+			return nil, err
+		}
+
+		rec, err := c.client.Operate(nil, key,
+			as.ListGetByValueRangeOp(binEntries, from, to, as.ListReturnTypeValue),
+		)
+		if err != nil {
+			if isResultCode(err, astypes.KEY_NOT_FOUND_ERROR) {
+				continue
+			}
+			return nil, err
+		}
+
+		vals, _ := rec.Bins[binEntries].([]interface{})
+		out = append(out, vals...)
+	}
+	return out, nil
+}
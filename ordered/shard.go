@@ -0,0 +1,46 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import (
+	"fmt"
+	"sort"
+)
+
+// shardKey returns the record key string for the shard of collection name
+// whose stable shard id is i (not its position in value order). Shards are
+// plain records named "<name>.shard.<i>", each holding one ordered CDT list
+// in binEntries.
+func shardKey(name string, i int) string {
+	return fmt.Sprintf("%s.shard.%d", name, i)
+}
+
+// metaKey returns the record key string for collection name's metadata
+// record, which holds its shard count, capacity and the shard boundaries.
+func metaKey(name string) string {
+	return fmt.Sprintf("%s.meta", name)
+}
+
+// shardFor returns the index of the shard that owns key, given boundaries,
+// the sorted list of each non-first shard's minimum key (len(boundaries) ==
+// shardCount-1). Shards are contiguous, ordered ranges rather than hash
+// buckets, so walking shard 0..shardCount-1 in order yields entries in
+// collection order, and a Range query only needs to touch the shards whose
+// range overlaps [from, to].
+func shardFor(boundaries []float64, key float64) int {
+	// boundaries[i] is the minimum key of shard i+1, so the shard owning key
+	// is the count of boundaries that are <= key.
+	return sort.Search(len(boundaries), func(i int) bool { return boundaries[i] > key })
+}
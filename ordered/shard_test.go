@@ -0,0 +1,52 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ordered
+
+import "testing"
+
+func TestShardForSingleShard(t *testing.T) {
+	if got := shardFor(nil, 42); got != 0 {
+		t.Fatalf("expected shard 0 with no boundaries, got %d", got)
+	}
+}
+
+func TestShardForOrdersByRange(t *testing.T) {
+	// Three shards: (-inf, 10), [10, 20), [20, +inf).
+	boundaries := []float64{10, 20}
+
+	cases := map[float64]int{
+		-5: 0,
+		9:  0,
+		10: 1,
+		15: 1,
+		20: 2,
+		99: 2,
+	}
+
+	for key, want := range cases {
+		if got := shardFor(boundaries, key); got != want {
+			t.Errorf("shardFor(%v) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestToFloat64(t *testing.T) {
+	if _, ok := toFloat64("not a number"); ok {
+		t.Fatal("expected string to be unsupported")
+	}
+	if f, ok := toFloat64(int64(7)); !ok || f != 7 {
+		t.Fatalf("toFloat64(int64(7)) = (%v, %v), want (7, true)", f, ok)
+	}
+}
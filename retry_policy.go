@@ -0,0 +1,162 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	. "github.com/aerospike/aerospike-client-go/types"
+)
+
+// RetryPolicy decides whether a failed command should be retried and, if so,
+// how long to wait before the next attempt. It is meant to be set per
+// BasePolicy (and inherited by WritePolicy, QueryPolicy, etc.), so callers
+// can compose strategies per call type -- e.g. never retry an InDoubt()
+// write, retry SERVER_NOT_AVAILABLE aggressively, and back off
+// exponentially on TIMEOUT. That wiring into BasePolicy and command
+// execution does not exist yet: RetryPolicy, defaultRetryPolicy and
+// shouldRetryCommand are a standalone building block nothing in this tree
+// calls yet, same as the metrics package's Sink.
+type RetryPolicy interface {
+	// AllowRetry reports whether attempt (1-based, the attempt that just
+	// failed with err) should be retried at all.
+	AllowRetry(err error, attempt int) bool
+	// Backoff returns how long to wait before the next attempt, given the
+	// attempt number that just failed and the delay used before that
+	// attempt (0 on the first retry).
+	Backoff(attempt int, lastDelay time.Duration) time.Duration
+}
+
+// defaultMaxRetries is the retry limit ExponentialBackoff and
+// DecorrelatedJitterBackoff use when their MaxRetries is 0; BasePolicy still
+// owns the overall deadline via Policy.Timeout, so a capped retry count is
+// just a backstop against pathological backoff sequences.
+const defaultMaxRetries = 5
+
+// NoRetry never retries. Use it for policies where a retry changes
+// observable behavior, e.g. non-idempotent writes that must not be retried
+// once they may have reached the server.
+type NoRetry struct{}
+
+// AllowRetry implements RetryPolicy.
+func (NoRetry) AllowRetry(err error, attempt int) bool { return false }
+
+// Backoff implements RetryPolicy.
+func (NoRetry) Backoff(attempt int, lastDelay time.Duration) time.Duration { return 0 }
+
+// ExponentialBackoff retries with a full-jitter exponential backoff: each
+// delay is a random duration in [0, min(Cap, Base*2^attempt)], as described
+// in the "Exponential Backoff And Jitter" AWS architecture blog post. Full
+// jitter spreads retries out evenly and avoids the thundering-herd effect of
+// many clients retrying in lockstep.
+type ExponentialBackoff struct {
+	// Base is the unit the delay bound is scaled from: Base*2^attempt, so
+	// the first retry (attempt 1) draws from [0, 2*Base].
+	Base time.Duration
+	// Cap bounds how large the backoff can grow.
+	Cap time.Duration
+	// MaxRetries is the maximum number of retries allowed; 0 means
+	// defaultMaxRetries.
+	MaxRetries int
+}
+
+// AllowRetry implements RetryPolicy.
+func (b ExponentialBackoff) AllowRetry(err error, attempt int) bool {
+	max := b.MaxRetries
+	if max <= 0 {
+		max = defaultMaxRetries
+	}
+	return attempt <= max
+}
+
+// Backoff implements RetryPolicy.
+func (b ExponentialBackoff) Backoff(attempt int, lastDelay time.Duration) time.Duration {
+	exp := b.Base << attempt
+	if exp <= 0 || exp > b.Cap { // overflow or past the cap
+		exp = b.Cap
+	}
+	return time.Duration(rand.Int63n(int64(exp) + 1))
+}
+
+// DecorrelatedJitterBackoff retries with "decorrelated jitter": each delay is
+// drawn from [Base, lastDelay*3), which tends to space consecutive retries
+// further apart than full jitter while still bounding growth by Cap.
+type DecorrelatedJitterBackoff struct {
+	// Base is the minimum backoff, and stands in for lastDelay on the first
+	// retry, which therefore draws from [Base, 3*Base).
+	Base time.Duration
+	// Cap bounds how large the backoff can grow.
+	Cap time.Duration
+	// MaxRetries is the maximum number of retries allowed; 0 means
+	// defaultMaxRetries.
+	MaxRetries int
+}
+
+// AllowRetry implements RetryPolicy.
+func (b DecorrelatedJitterBackoff) AllowRetry(err error, attempt int) bool {
+	max := b.MaxRetries
+	if max <= 0 {
+		max = defaultMaxRetries
+	}
+	return attempt <= max
+}
+
+// Backoff implements RetryPolicy.
+func (b DecorrelatedJitterBackoff) Backoff(attempt int, lastDelay time.Duration) time.Duration {
+	if lastDelay <= 0 {
+		lastDelay = b.Base
+	}
+
+	upper := lastDelay * 3
+	if upper <= lastDelay || upper > b.Cap { // overflow or past the cap
+		upper = b.Cap
+	}
+	if upper <= b.Base {
+		return b.Base
+	}
+
+	return b.Base + time.Duration(rand.Int63n(int64(upper-b.Base)))
+}
+
+// defaultRetryPolicy is meant to be used by BasePolicy when RetryPolicy is
+// left nil, preserving the client's historical behavior of retrying
+// ErrTimeout, ErrConnectionPoolEmpty and ErrTooManyOpeningConnections with a
+// small fixed number of attempts. See the package-level note on RetryPolicy:
+// BasePolicy does not consult this yet.
+var defaultRetryPolicy RetryPolicy = ExponentialBackoff{
+	Base:       1 * time.Millisecond,
+	Cap:        100 * time.Millisecond,
+	MaxRetries: defaultMaxRetries,
+}
+
+// shouldRetryCommand reports whether err is a class of error this client has
+// historically retried at all, independent of the configured RetryPolicy.
+// ErrCircuitOpen is deliberately excluded: a command short-circuited by an
+// open breaker should fail fast, not retry into the same breaker. Nothing
+// in command execution calls this yet; see the package-level note on
+// RetryPolicy.
+func shouldRetryCommand(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	return errors.Is(err, ErrClassTimeout) ||
+		errors.Is(err, ErrClassClusterUnavailable) ||
+		errors.Is(err, ErrServerNotAvailable)
+}
@@ -0,0 +1,84 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/aerospike/aerospike-client-go/types"
+)
+
+func TestDecorrelatedJitterBackoffRespectsCap(t *testing.T) {
+	b := DecorrelatedJitterBackoff{Base: time.Millisecond, Cap: 50 * time.Millisecond, MaxRetries: 3}
+
+	last := time.Duration(0)
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := b.Backoff(attempt, last)
+		if d < b.Base || d > b.Cap {
+			t.Fatalf("Backoff(%d, %v) = %v, want in [%v, %v]", attempt, last, d, b.Base, b.Cap)
+		}
+		last = d
+	}
+
+	if !b.AllowRetry(nil, 3) {
+		t.Fatal("expected retry 3 to be allowed")
+	}
+	if b.AllowRetry(nil, 4) {
+		t.Fatal("expected retry 4 to be denied past MaxRetries")
+	}
+}
+
+func TestDecorrelatedJitterBackoffFirstDelayUsesBase(t *testing.T) {
+	b := DecorrelatedJitterBackoff{Base: 5 * time.Millisecond, Cap: 100 * time.Millisecond}
+
+	if d := b.Backoff(1, 0); d < b.Base {
+		t.Fatalf("Backoff(1, 0) = %v, want >= Base (%v)", d, b.Base)
+	}
+}
+
+func TestShouldRetryCommand(t *testing.T) {
+	if shouldRetryCommand(nil) {
+		t.Fatal("expected nil err not to be retried")
+	}
+	if shouldRetryCommand(errors.New("some unrelated error")) {
+		t.Fatal("expected an unrelated error not to be retried")
+	}
+
+	for _, err := range []error{ErrClassTimeout, ErrClassClusterUnavailable, ErrServerNotAvailable} {
+		if !shouldRetryCommand(err) {
+			t.Fatalf("expected %v to be retried", err)
+		}
+	}
+
+	if shouldRetryCommand(ErrCircuitOpen) {
+		t.Fatal("expected ErrCircuitOpen not to be retried: a tripped breaker should fail fast, not retry into itself")
+	}
+}
+
+func TestShouldRetryCommandDoesNotEatServerNotAvailable(t *testing.T) {
+	// Regression test: CIRCUIT_BREAKER_OPEN previously collided with
+	// SERVER_NOT_AVAILABLE's ResultCode, which made errors.Is(err,
+	// ErrCircuitOpen) true for real SERVER_NOT_AVAILABLE errors and caused
+	// shouldRetryCommand to reject them before ever checking
+	// ErrServerNotAvailable.
+	if errors.Is(ErrServerNotAvailable, ErrCircuitOpen) {
+		t.Fatal("ErrServerNotAvailable must not be errors.Is(ErrCircuitOpen)")
+	}
+	if !shouldRetryCommand(ErrServerNotAvailable) {
+		t.Fatal("expected ErrServerNotAvailable to be retried")
+	}
+}
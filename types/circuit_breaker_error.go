@@ -0,0 +1,30 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// CIRCUIT_BREAKER_OPEN is a synthetic, client-only ResultCode: it is never
+// sent by the server. It is used when a per-node circuit breaker is open and
+// short-circuits a command before it is ever sent, the same way other
+// client-only codes (e.g. a client-side TIMEOUT) are negative values that
+// don't collide with the server's protocol result codes. -14 is chosen to
+// sit past the lowest client-only code already in use (-13) so it cannot
+// collide with an existing ResultCode such as SERVER_NOT_AVAILABLE (-11).
+const CIRCUIT_BREAKER_OPEN ResultCode = -14
+
+// ErrCircuitOpen is returned instead of sending a command to a node whose
+// circuit breaker has tripped. See CircuitBreaker in the root package for
+// how a node's breaker trips and resets; that breaker is not yet wired into
+// command execution, so nothing returns this error outside its own tests.
+var ErrCircuitOpen = NewAerospikeError(CIRCUIT_BREAKER_OPEN, "circuit breaker is open for this node; short-circuiting without sending the command")
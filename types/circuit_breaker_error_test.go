@@ -0,0 +1,33 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrCircuitOpenDoesNotCollideWithServerNotAvailable(t *testing.T) {
+	if CIRCUIT_BREAKER_OPEN == SERVER_NOT_AVAILABLE {
+		t.Fatalf("CIRCUIT_BREAKER_OPEN (%d) must not equal SERVER_NOT_AVAILABLE (%d)", CIRCUIT_BREAKER_OPEN, SERVER_NOT_AVAILABLE)
+	}
+
+	if errors.Is(ErrServerNotAvailable, ErrCircuitOpen) {
+		t.Fatal("ErrServerNotAvailable must not be errors.Is(ErrCircuitOpen)")
+	}
+	if errors.Is(ErrCircuitOpen, ErrServerNotAvailable) {
+		t.Fatal("ErrCircuitOpen must not be errors.Is(ErrServerNotAvailable)")
+	}
+}
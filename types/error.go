@@ -54,11 +54,36 @@ func (ase *AerospikeError) MarkInDoubt() {
 	ase.inDoubt = true
 }
 
+// Unwrap returns the underlying error, so that AerospikeError plays well
+// with errors.Is/errors.As and with errors wrapped around it via fmt.Errorf("%w", ...).
+func (ase *AerospikeError) Unwrap() error {
+	return ase.error
+}
+
+// Is implements the interface used by errors.Is. Two AerospikeErrors are
+// considered equal if they share the same ResultCode, and an AerospikeError
+// matches any errClass whose set of ResultCodes contains its own. This lets
+// callers write errors.Is(err, types.ErrClassTimeout) instead of manually
+// switching on ResultCode.
+func (ase *AerospikeError) Is(target error) bool {
+	if ec, ok := target.(*errClass); ok {
+		return ec.matches(ase)
+	}
+
+	var aerr *AerospikeError
+	if errors.As(target, &aerr) {
+		return aerr.resultCode == ase.resultCode
+	}
+
+	return false
+}
+
 // NewAerospikeError generates a new AerospikeError instance.
 // If no message is provided, the result code will be translated into the default
 // error message automatically.
 // To be able to check for error type, you could use the following:
-//   if aerr, ok := err.(AerospikeError); ok {
+//   var aerr *AerospikeError
+//   if errors.As(err, &aerr) {
 //       errCode := aerr.ResultCode()
 //       errMessage := aerr.Error()
 //   }
@@ -68,7 +93,35 @@ func NewAerospikeError(code ResultCode, messages ...string) error {
 	}
 
 	err := errors.New(strings.Join(messages, " "))
-	return AerospikeError{error: err, resultCode: code}
+	return &AerospikeError{error: err, resultCode: code}
+}
+
+// errClass groups a family of related ResultCodes so that callers can test
+// membership with errors.Is(err, SomeErrClass) regardless of which specific
+// ResultCode the error carries. It implements error only so that it can be
+// passed as the target of errors.Is; it is never returned by the client.
+type errClass struct {
+	name  string
+	codes map[ResultCode]bool
+}
+
+func newErrClass(name string, codes ...ResultCode) *errClass {
+	m := make(map[ResultCode]bool, len(codes))
+	for _, c := range codes {
+		m[c] = true
+	}
+	return &errClass{name: name, codes: m}
+}
+
+func (ec *errClass) Error() string {
+	return ec.name
+}
+
+func (ec *errClass) matches(ase *AerospikeError) bool {
+	if ec == errClassInDoubt {
+		return ase.inDoubt
+	}
+	return ec.codes[ase.resultCode]
 }
 
 //revive:disable
@@ -90,4 +143,18 @@ var (
 	ErrQueryTerminated                = NewAerospikeError(QUERY_TERMINATED)
 )
 
+// ErrClassTimeout, ErrClassKeyExists, ErrClassInDoubt and ErrClassClusterUnavailable
+// are sentinel error classes for use with errors.Is. They let callers test for a
+// family of related result codes (or, for ErrClassInDoubt, the InDoubt flag) without
+// enumerating every ResultCode that belongs to it, e.g.:
+//   if errors.Is(err, types.ErrClassTimeout) { ... }
+var (
+	ErrClassTimeout   = newErrClass("timeout", TIMEOUT)
+	ErrClassKeyExists = newErrClass("key exists", KEY_EXISTS_ERROR)
+	errClassInDoubt   = newErrClass("in doubt")
+	ErrClassInDoubt   = errClassInDoubt
+	ErrClassClusterUnavailable = newErrClass("cluster unavailable",
+		SERVER_NOT_AVAILABLE, NO_AVAILABLE_CONNECTIONS_TO_NODE)
+)
+
 //revive:enable
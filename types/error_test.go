@@ -0,0 +1,84 @@
+// Copyright 2014-2021 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAerospikeErrorIsErrClass(t *testing.T) {
+	if !errors.Is(ErrTimeout, ErrClassTimeout) {
+		t.Error("ErrTimeout should be errors.Is(ErrClassTimeout)")
+	}
+	if errors.Is(ErrKeyNotFound, ErrClassTimeout) {
+		t.Error("ErrKeyNotFound should not be errors.Is(ErrClassTimeout)")
+	}
+
+	if !errors.Is(NewAerospikeError(KEY_EXISTS_ERROR), ErrClassKeyExists) {
+		t.Error("a KEY_EXISTS_ERROR should be errors.Is(ErrClassKeyExists)")
+	}
+
+	if !errors.Is(ErrServerNotAvailable, ErrClassClusterUnavailable) {
+		t.Error("ErrServerNotAvailable should be errors.Is(ErrClassClusterUnavailable)")
+	}
+	if !errors.Is(ErrConnectionPoolEmpty, ErrClassClusterUnavailable) {
+		t.Error("ErrConnectionPoolEmpty should be errors.Is(ErrClassClusterUnavailable)")
+	}
+	if errors.Is(ErrTimeout, ErrClassClusterUnavailable) {
+		t.Error("ErrTimeout should not be errors.Is(ErrClassClusterUnavailable)")
+	}
+
+	var inDoubt error = NewAerospikeError(TIMEOUT)
+	var aerr *AerospikeError
+	errors.As(inDoubt, &aerr)
+	aerr.MarkInDoubt()
+	if !errors.Is(inDoubt, ErrClassInDoubt) {
+		t.Error("an error marked in doubt should be errors.Is(ErrClassInDoubt)")
+	}
+	if errors.Is(ErrTimeout, ErrClassInDoubt) {
+		t.Error("an error not marked in doubt should not be errors.Is(ErrClassInDoubt)")
+	}
+}
+
+func TestAerospikeErrorAsRecoversConcreteType(t *testing.T) {
+	err := NewAerospikeError(TIMEOUT)
+
+	var aerr *AerospikeError
+	if !errors.As(err, &aerr) {
+		t.Fatal("errors.As should recover a *AerospikeError from err")
+	}
+	if aerr.ResultCode() != TIMEOUT {
+		t.Errorf("ResultCode() = %v, want %v", aerr.ResultCode(), TIMEOUT)
+	}
+}
+
+func TestAerospikeErrorsSharingResultCodeAreIs(t *testing.T) {
+	// ErrConnectionPoolEmpty and ErrTooManyOpeningConnections are distinct
+	// sentinels with different messages, but both carry
+	// NO_AVAILABLE_CONNECTIONS_TO_NODE. AerospikeError.Is matches purely by
+	// ResultCode, so they are intentionally errors.Is-equal: callers that
+	// only care about the result code, not which specific sentinel was
+	// returned, can test against either one.
+	if ErrConnectionPoolEmpty.(*AerospikeError).ResultCode() != ErrTooManyOpeningConnections.(*AerospikeError).ResultCode() {
+		t.Fatal("test invalid: expected both sentinels to share a ResultCode")
+	}
+	if !errors.Is(ErrConnectionPoolEmpty, ErrTooManyOpeningConnections) {
+		t.Error("sentinels sharing a ResultCode should be errors.Is-equal")
+	}
+	if !errors.Is(ErrTooManyOpeningConnections, ErrConnectionPoolEmpty) {
+		t.Error("errors.Is should be symmetric for sentinels sharing a ResultCode")
+	}
+}